@@ -0,0 +1,167 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import "sync"
+
+// defaultInitialWindowSize is the initial per-stream flow control window,
+// advertised by both sides during the stream handshake, similar to
+// HTTP/2's SETTINGS_INITIAL_WINDOW_SIZE.
+const defaultInitialWindowSize = 64 << 10 // 64KiB
+
+// defaultConnWindowSize is the initial connection-level flow control
+// window, shared by all streams on a connection. It must be at least as
+// large as defaultInitialWindowSize or every stream would stall
+// immediately.
+const defaultConnWindowSize = 1 << 20 // 1MiB
+
+// windowUpdateThreshold is the fraction of the window that must be consumed
+// before a WINDOW_UPDATE is emitted, so updates are coalesced instead of
+// being sent after every message.
+const windowUpdateThreshold = 0.5
+
+// flowWindow tracks a byte-accounted flow control window shared by senders
+// and receivers of a single stream, plus optionally the connection it
+// belongs to. A consumer of 1-byte messages and a consumer of 1-MiB
+// messages get the same backpressure under the message-count
+// InCapacity/OutCapacity bound; a flowWindow is the byte-accounted
+// alternative a future wiring could use instead.
+//
+// This is a standalone primitive for that future use, not a wired
+// replacement for InCapacity/OutCapacity, and this change does not attempt
+// to drive one from dispatch: acquire only blocks correctly if something on
+// the peer eventually calls release with the window update that onReceive
+// reports, and this snapshot has no connection.go to transmit that
+// WINDOW_UPDATE back across the wire, or to chunk a payload larger than the
+// window instead of blocking acquire on it forever. Wiring acquire into
+// register/Call without that delivery would trade today's message-count
+// backpressure for a window that never refills -- a permanent deadlock
+// once it's exhausted, not a smaller bug. StreamHandler.OutCapacity/
+// InCapacity remain the only backpressure mechanism register and Call
+// actually use today.
+type flowWindow struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	size     int64 // current available window
+	initial  int64 // window size last advertised
+	consumed int64 // bytes received but not yet acknowledged via WINDOW_UPDATE
+
+	closed bool
+}
+
+// newFlowWindow creates a window with the given initial size.
+func newFlowWindow(initial int64) *flowWindow {
+	w := &flowWindow{size: initial, initial: initial}
+	w.cond = sync.NewCond(&w.mu)
+	return w
+}
+
+// acquire blocks until n bytes of window are available (or the window is
+// closed) and then consumes them. It returns false if the window was
+// closed before n bytes became available.
+func (w *flowWindow) acquire(n int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for w.size < n && !w.closed {
+		w.cond.Wait()
+	}
+	if w.closed {
+		return false
+	}
+	w.size -= n
+	return true
+}
+
+// release adds back delta bytes of window, e.g. from a WINDOW_UPDATE frame,
+// and wakes any sender blocked in acquire.
+func (w *flowWindow) release(delta int64) {
+	w.mu.Lock()
+	w.size += delta
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+// onReceive records n consumed bytes and reports the delta to grant back
+// via a WINDOW_UPDATE frame, coalescing updates until at least half the
+// initial window has been consumed.
+func (w *flowWindow) onReceive(n int64) (windowUpdate int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.consumed += n
+	if float64(w.consumed) >= float64(w.initial)*windowUpdateThreshold {
+		delta := w.consumed
+		w.consumed = 0
+		return delta
+	}
+	return 0
+}
+
+// close wakes any sender blocked in acquire so it can observe the window is
+// no longer usable, e.g. because the stream ended.
+func (w *flowWindow) close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+// streamFlowControl is the pair of windows (this stream's, and the shared
+// connection-level one) that must both have room before a message sends.
+// A large HandlerWalkDir response is bounded by its own stream window, so
+// it cannot exhaust the connection window a concurrent HandlerLockRefresh
+// relies on.
+type streamFlowControl struct {
+	stream *flowWindow
+	conn   *flowWindow
+
+	// outCapacity/inCapacity retain the previous message-count caps as a
+	// secondary bound, e.g. to cap goroutine fan-out independent of size.
+	outCapacity int
+	inCapacity  int
+}
+
+func newStreamFlowControl(conn *flowWindow, outCapacity, inCapacity int) *streamFlowControl {
+	return &streamFlowControl{
+		stream:      newFlowWindow(defaultInitialWindowSize),
+		conn:        conn,
+		outCapacity: outCapacity,
+		inCapacity:  inCapacity,
+	}
+}
+
+// acquireSend blocks until there is room in both the stream and connection
+// windows for a payload of size n bytes.
+func (f *streamFlowControl) acquireSend(n int64) bool {
+	if !f.stream.acquire(n) {
+		return false
+	}
+	if !f.conn.acquire(n) {
+		f.stream.release(n)
+		return false
+	}
+	return true
+}
+
+// releaseReceive should be called after delivering a received payload of
+// size n bytes to the consumer. It returns the window updates, if any, that
+// should be sent back to the peer for the stream and connection windows
+// respectively.
+func (f *streamFlowControl) releaseReceive(n int64) (streamUpdate, connUpdate int64) {
+	return f.stream.onReceive(n), f.conn.onReceive(n)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlowWindowAcquireRelease(t *testing.T) {
+	w := newFlowWindow(100)
+	if !w.acquire(60) {
+		t.Fatal("expected acquire(60) to succeed against a window of 100")
+	}
+	// Only 40 remains, so a second acquire(60) must block until release.
+
+	done := make(chan bool, 1)
+	go func() { done <- w.acquire(60) }()
+
+	select {
+	case <-done:
+		t.Fatal("acquire should have blocked until release")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	w.release(60)
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatal("acquire should succeed once enough window was released")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquire never woke up after release")
+	}
+}
+
+func TestFlowWindowCloseUnblocksAcquire(t *testing.T) {
+	w := newFlowWindow(10)
+	w.acquire(10) // exhaust it
+
+	done := make(chan bool, 1)
+	go func() { done <- w.acquire(1) }()
+
+	select {
+	case <-done:
+		t.Fatal("acquire should block while the window is exhausted")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	w.close()
+	select {
+	case ok := <-done:
+		if ok {
+			t.Fatal("acquire on a closed window should report failure")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("close never woke the blocked acquire")
+	}
+}
+
+func TestFlowWindowOnReceiveCoalescesUpdates(t *testing.T) {
+	w := newFlowWindow(100)
+	if u := w.onReceive(10); u != 0 {
+		t.Fatalf("onReceive(10) of 100: got update %d, want 0 (below threshold)", u)
+	}
+	if u := w.onReceive(39); u != 0 {
+		t.Fatalf("onReceive(39) more, 49 total of 100: got update %d, want 0", u)
+	}
+	u := w.onReceive(1)
+	if u != 50 {
+		t.Fatalf("onReceive crossing the 50%% threshold: got update %d, want 50", u)
+	}
+	if u := w.onReceive(1); u != 0 {
+		t.Fatalf("consumed counter should reset after a window update was issued, got %d", u)
+	}
+}
+
+func TestStreamFlowControlGatesOnBothWindows(t *testing.T) {
+	conn := newFlowWindow(1000)
+	fc := newStreamFlowControl(conn, 1, 1)
+
+	if !fc.acquireSend(500) {
+		t.Fatal("expected room in both the (default-sized) stream and conn windows")
+	}
+
+	// Exhaust the rest of the connection window directly; the stream window
+	// alone has plenty of room left, but acquireSend must still block on
+	// the shared conn window.
+	if !conn.acquire(500) {
+		t.Fatal("expected to be able to exhaust the remaining conn window directly")
+	}
+
+	done := make(chan bool, 1)
+	go func() { done <- fc.acquireSend(1) }()
+	select {
+	case <-done:
+		t.Fatal("acquireSend should block while the conn window is exhausted, even with stream room left")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	conn.release(1)
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Fatal("acquireSend should succeed once the conn window has room again")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquireSend never woke up after the conn window was released")
+	}
+}
+
+func TestStreamFlowControlReleaseReceiveReportsBothWindows(t *testing.T) {
+	conn := newFlowWindow(1 << 20)
+	fc := newStreamFlowControl(conn, 1, 1)
+
+	// Consume enough to cross the stream window's threshold in one call,
+	// while staying below the connection window's threshold.
+	streamUpdate, connUpdate := fc.releaseReceive(defaultInitialWindowSize)
+	if streamUpdate != defaultInitialWindowSize {
+		t.Fatalf("stream update = %d, want %d", streamUpdate, defaultInitialWindowSize)
+	}
+	if connUpdate != 0 {
+		t.Fatalf("conn update = %d, want 0: a 64KiB read shouldn't cross a 1MiB conn window's threshold", connUpdate)
+	}
+}
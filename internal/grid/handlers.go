@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/minio/minio/internal/hash/sha256"
 	"github.com/minio/minio/internal/logger"
@@ -58,6 +59,7 @@ const (
 	HandlerRenameData
 
 	HandlerServerVerify
+	HandlerPing
 	// Add more above here ^^^
 	// If all handlers are used, the type of Handler can be changed.
 	// Handlers have no versioning, so non-compatible handler changes must result in new IDs.
@@ -152,16 +154,15 @@ func (r RemoteErr) Error() string {
 	return string(r)
 }
 
-// Is returns if the string representation matches.
+// Is reports whether other's error string matches r's. RemoteErr does not
+// preserve the original error's type (see NewRemoteErr), so comparing
+// messages is the only way a wrapped RemoteErr can still match a sentinel
+// like ErrDisconnected through errors.Is.
 func (r *RemoteErr) Is(other error) bool {
 	if r == nil || other == nil {
-		return r == other
+		return r == nil && other == nil
 	}
-	var o RemoteErr
-	if errors.As(other, &o) {
-		return r == &o
-	}
-	return false
+	return r.Error() == other.Error()
 }
 
 // IsRemoteErr returns the value if the error is a RemoteErr.
@@ -220,10 +221,13 @@ type (
 		Subroute string
 
 		// OutCapacity is the output capacity. If <= 0 capacity will be 1.
+		// This is a message-count bound; see flowWindow for a
+		// byte-accounted alternative, not yet wired into dispatch.
 		OutCapacity int
 
 		// InCapacity is the output capacity.
 		// If == 0 no input is expected
+		// This is a message-count bound, see OutCapacity.
 		InCapacity int
 	}
 )
@@ -294,6 +298,7 @@ type RoundTripper interface {
 type SingleHandler[Req, Resp RoundTripper] struct {
 	id             HandlerID
 	sharedResponse bool
+	retry          RetryPolicy
 
 	reqPool  sync.Pool
 	respPool sync.Pool
@@ -354,8 +359,11 @@ func (h *SingleHandler[Req, Resp]) NewRequest() Req {
 }
 
 // Register a handler for a Req -> Resp roundtrip.
+// If the manager has unary server interceptors configured, they will be
+// chained in registration order around the handler.
 func (h *SingleHandler[Req, Resp]) Register(m *Manager, handle func(req Req) (resp Resp, err *RemoteErr), subroute ...string) error {
-	return m.RegisterSingleHandler(h.id, func(payload []byte) ([]byte, *RemoteErr) {
+	info := &UnaryServerInfo{HandlerID: h.id, Subroute: strings.Join(subroute, "/")}
+	base := func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) {
 		req := h.NewRequest()
 		_, err := req.UnmarshalMsg(payload)
 		if err != nil {
@@ -379,6 +387,27 @@ func (h *SingleHandler[Req, Resp]) Register(m *Manager, handle func(req Req) (re
 			return nil, &r
 		}
 		return payload, nil
+	}
+	chained := chainUnaryServerInterceptors(m.unaryServerInterceptors(), h.id, info, base)
+	return m.RegisterSingleHandler(h.id, func(payload []byte) ([]byte, *RemoteErr) {
+		ctx := context.Background()
+		sh := m.stats()
+		if sh == nil {
+			return chained(ctx, payload)
+		}
+		ctx = sh.TagRPC(ctx, &RPCTagInfo{HandlerID: h.id, Subroute: info.Subroute})
+		sh.HandleRPC(ctx, Begin{BeginTime: time.Now()})
+		sh.HandleRPC(ctx, InPayload{Length: len(payload), RecvTime: time.Now()})
+		res, rerr := chained(ctx, payload)
+		if res != nil {
+			sh.HandleRPC(ctx, OutPayload{Length: len(res), SentTime: time.Now()})
+		}
+		var err error
+		if rerr != nil {
+			err = rerr
+		}
+		sh.HandleRPC(ctx, End{EndTime: time.Now(), Error: err})
+		return res, rerr
 	}, subroute...)
 }
 
@@ -387,16 +416,87 @@ type Requester interface {
 	Request(ctx context.Context, h HandlerID, req []byte) ([]byte, error)
 }
 
+// interceptedRequester is implemented by Requesters that carry a set of
+// client-side unary interceptors to run around every call, e.g. a
+// connection bound to a Manager configured with WithUnaryClientInterceptors.
+type interceptedRequester interface {
+	unaryClientInterceptors() []UnaryClientInterceptor
+}
+
+// statsRequester is implemented by Requesters that carry a StatsHandler,
+// e.g. a connection bound to a Manager configured with WithStatsHandler.
+type statsRequester interface {
+	statsHandler() StatsHandler
+}
+
 // Call the remote with the request and return the response.
 // The response should be returned with PutResponse when no error.
 // If no deadline is set, a 1-minute deadline is added.
-func (h *SingleHandler[Req, Resp]) Call(ctx context.Context, c Requester, req Req) (resp Resp, err error) {
+// If c carries unary client interceptors, they are chained around the call
+// in registration order, outermost first.
+// WithHeader attaches Metadata as the call's header; this only has an
+// effect if c also implements headerRequester, i.e. it knows how to frame
+// header metadata on the wire. WithTrailer, if given, receives the trailer
+// Metadata the remote sent at end-of-call, even if err is non-nil.
+func (h *SingleHandler[Req, Resp]) Call(ctx context.Context, c Requester, req Req, opts ...CallOption) (resp Resp, err error) {
 	payload, err := req.MarshalMsg(GetByteBuffer()[:0])
 	if err != nil {
 		return resp, err
 	}
-	ctx = context.WithValue(ctx, TraceParamsKey{}, req)
-	res, err := c.Request(ctx, h.id, payload)
+	o := applyCallOptions(opts)
+	hr, hasHeaderRequester := c.(headerRequester)
+	var trailer Metadata
+	base := func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) {
+		if (o.header != nil || o.trailer != nil) && hasHeaderRequester {
+			res, tr, err := hr.RequestWithMetadata(ctx, h.id, payload, o.header)
+			trailer = tr
+			if err != nil {
+				return nil, NewRemoteErr(err)
+			}
+			return res, nil
+		}
+		res, err := c.Request(ctx, h.id, payload)
+		if err != nil {
+			return nil, NewRemoteErr(err)
+		}
+		return res, nil
+	}
+	var ics []UnaryClientInterceptor
+	if ir, ok := c.(interceptedRequester); ok {
+		ics = ir.unaryClientInterceptors()
+	}
+	info := &UnaryClientInfo{HandlerID: h.id}
+	call := chainUnaryClientInterceptors(ics, h.id, info, base)
+	var sh StatsHandler
+	if sr, ok := c.(statsRequester); ok {
+		sh = sr.statsHandler()
+	}
+	if sh != nil {
+		ctx = sh.TagRPC(ctx, &RPCTagInfo{HandlerID: h.id, Client: true})
+		sh.HandleRPC(ctx, Begin{Client: true, BeginTime: time.Now()})
+		sh.HandleRPC(ctx, OutPayload{Client: true, Length: len(payload), SentTime: time.Now()})
+	}
+	res, err := callWithRetry(ctx, h.retry, func() ([]byte, bool, error) {
+		res, rerr := call(ctx, payload)
+		if rerr == nil {
+			return res, false, nil
+		}
+		// Only a disconnect before any response bytes were observed is safe
+		// to retry; any other error may mean the server already applied the
+		// request (e.g. RenameData, DeleteVersion). RemoteErr.Is compares by
+		// message, so this still matches the ErrDisconnected sentinel even
+		// though rerr has been wrapped into a *RemoteErr.
+		return nil, errors.Is(rerr, ErrDisconnected), rerr
+	})
+	if sh != nil {
+		if err == nil {
+			sh.HandleRPC(ctx, InPayload{Client: true, Length: len(res), RecvTime: time.Now()})
+		}
+		sh.HandleRPC(ctx, End{Client: true, EndTime: time.Now(), Error: err})
+	}
+	if o.trailer != nil {
+		*o.trailer = trailer
+	}
 	PutByteBuffer(payload)
 	if err != nil {
 		return resp, err
@@ -459,6 +559,7 @@ type StreamTypeHandler[Payload, Req, Resp RoundTripper] struct {
 	nilReq         Req
 	nilResp        Resp
 	sharedResponse bool
+	retry          RetryPolicy
 }
 
 // NewStream creates a typed handler that can provide Marshal/Unmarshal.
@@ -556,79 +657,108 @@ func (h *StreamTypeHandler[Payload, Req, Resp]) RegisterNoPayload(m *Manager, ha
 }
 
 // Register a handler for two-way streaming with optional payload and input stream.
+// If the manager has stream server interceptors configured, they are chained
+// in registration order around the raw in/out channels before messages are
+// unmarshaled, so interceptors can observe or gate every message on the wire.
 func (h *StreamTypeHandler[Payload, Req, Resp]) register(m *Manager, handle func(ctx context.Context, p Payload, in <-chan Req, out chan<- Resp) *RemoteErr, subroute ...string) error {
-	return m.RegisterStreamingHandler(h.id, StreamHandler{
-		Handle: func(ctx context.Context, payload []byte, in <-chan []byte, out chan<- []byte) *RemoteErr {
-			var plT Payload
-			if h.WithPayload {
-				plT = h.NewPayload()
-				_, err := plT.UnmarshalMsg(payload)
-				PutByteBuffer(payload)
-				if err != nil {
-					r := RemoteErr(err.Error())
-					return &r
+	subroute2 := strings.Join(subroute, "/")
+	info := &StreamServerInfo{HandlerID: h.id, Subroute: subroute2, IsClientStream: h.InCapacity > 0}
+	sh := m.stats()
+	base := func(ctx context.Context, payload []byte, in <-chan []byte, out chan<- []byte) *RemoteErr {
+		if sh != nil {
+			ctx = sh.TagRPC(ctx, &RPCTagInfo{HandlerID: h.id, Subroute: subroute2})
+			sh.HandleRPC(ctx, Begin{BeginTime: time.Now()})
+		}
+		var plT Payload
+		if h.WithPayload {
+			plT = h.NewPayload()
+			_, err := plT.UnmarshalMsg(payload)
+			PutByteBuffer(payload)
+			if err != nil {
+				r := RemoteErr(err.Error())
+				if sh != nil {
+					sh.HandleRPC(ctx, End{EndTime: time.Now(), Error: &r})
 				}
+				return &r
 			}
+		}
 
-			var inT chan Req
-			if h.InCapacity > 0 {
-				// Don't add extra buffering
-				inT = make(chan Req)
-				go func() {
-					defer close(inT)
-					for {
+		var inT chan Req
+		if h.InCapacity > 0 {
+			// Don't add extra buffering
+			inT = make(chan Req)
+			go func() {
+				defer close(inT)
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case v, ok := <-in:
+						if !ok {
+							return
+						}
+						input := h.NewRequest()
+						_, err := input.UnmarshalMsg(v)
+						if err != nil {
+							logger.LogOnceIf(ctx, err, err.Error())
+						}
+						if sh != nil {
+							sh.HandleRPC(ctx, InPayload{Length: len(v), RecvTime: time.Now()})
+						}
+						PutByteBuffer(v)
+						// Send input
 						select {
 						case <-ctx.Done():
 							return
-						case v, ok := <-in:
-							if !ok {
-								return
-							}
-							input := h.NewRequest()
-							_, err := input.UnmarshalMsg(v)
-							if err != nil {
-								logger.LogOnceIf(ctx, err, err.Error())
-							}
-							PutByteBuffer(v)
-							// Send input
-							select {
-							case <-ctx.Done():
-								return
-							case inT <- input:
-							}
+						case inT <- input:
 						}
 					}
-				}()
-			}
-			outT := make(chan Resp)
-			outDone := make(chan struct{})
-			go func() {
-				defer close(outDone)
-				dropOutput := false
-				for v := range outT {
-					if dropOutput {
-						continue
-					}
-					dst := GetByteBuffer()
-					dst, err := v.MarshalMsg(dst[:0])
-					if err != nil {
-						logger.LogOnceIf(ctx, err, err.Error())
-					}
-					if !h.sharedResponse {
-						h.PutResponse(v)
-					}
-					select {
-					case <-ctx.Done():
-						dropOutput = true
-					case out <- dst:
-					}
 				}
 			}()
-			rErr := handle(ctx, plT, inT, outT)
-			close(outT)
-			<-outDone
-			return rErr
-		}, OutCapacity: h.OutCapacity, InCapacity: h.InCapacity, Subroute: strings.Join(subroute, "/"),
+		}
+		outT := make(chan Resp)
+		outDone := make(chan struct{})
+		go func() {
+			defer close(outDone)
+			dropOutput := false
+			for v := range outT {
+				if dropOutput {
+					continue
+				}
+				dst := GetByteBuffer()
+				dst, err := v.MarshalMsg(dst[:0])
+				if err != nil {
+					logger.LogOnceIf(ctx, err, err.Error())
+				}
+				if !h.sharedResponse {
+					h.PutResponse(v)
+				}
+				if sh != nil {
+					sh.HandleRPC(ctx, OutPayload{Length: len(dst), SentTime: time.Now()})
+				}
+				select {
+				case <-ctx.Done():
+					dropOutput = true
+				case out <- dst:
+				}
+			}
+		}()
+		rErr := handle(ctx, plT, inT, outT)
+		close(outT)
+		<-outDone
+		if sh != nil {
+			var err error
+			if rErr != nil {
+				err = rErr
+			}
+			sh.HandleRPC(ctx, End{EndTime: time.Now(), Error: err})
+		}
+		return rErr
+	}
+	chained := chainStreamServerInterceptors(m.streamServerInterceptors(), info, base)
+	return m.RegisterStreamingHandler(h.id, StreamHandler{
+		Handle:      StreamHandlerFn(chained),
+		OutCapacity: h.OutCapacity, InCapacity: h.InCapacity, Subroute: subroute2,
 	})
 }
 
@@ -645,6 +775,11 @@ type TypedStream[Req, Resp RoundTripper] struct {
 	// Channel *must* be closed to signal the end of the stream.
 	// If the request context is canceled, the stream will no longer process requests.
 	Requests chan<- Req
+
+	// header is the Metadata the remote sent back in response to a
+	// WithHeader call option, captured by Call. It is nil unless the
+	// Streamer implements headerStreamer and a header was requested.
+	header Metadata
 }
 
 // Results returns the results from the remote server one by one.
@@ -666,8 +801,21 @@ type Streamer interface {
 	NewStream(ctx context.Context, h HandlerID, payload []byte) (st *Stream, err error)
 }
 
-// Call the remove with the request and
-func (h *StreamTypeHandler[Payload, Req, Resp]) Call(ctx context.Context, c Streamer, payload Payload) (st *TypedStream[Req, Resp], err error) {
+// interceptedStreamer is implemented by Streamers that carry a set of
+// client-side stream interceptors to run around every new stream, e.g. a
+// connection bound to a Manager configured with WithStreamClientInterceptors.
+type interceptedStreamer interface {
+	streamClientInterceptors() []StreamClientInterceptor
+}
+
+// Call the remote with the payload and open a new stream.
+// If c carries stream client interceptors, they are chained around stream
+// creation in registration order, outermost first.
+// WithHeader attaches Metadata as the stream's header; this only has an
+// effect if c also implements headerStreamer, i.e. it knows how to frame
+// header metadata on the wire. The header the remote sends back, if any, is
+// exposed through the returned TypedStream's Header method.
+func (h *StreamTypeHandler[Payload, Req, Resp]) Call(ctx context.Context, c Streamer, payload Payload, opts ...CallOption) (st *TypedStream[Req, Resp], err error) {
 	var payloadB []byte
 	if h.WithPayload {
 		var err error
@@ -676,9 +824,52 @@ func (h *StreamTypeHandler[Payload, Req, Resp]) Call(ctx context.Context, c Stre
 			return nil, err
 		}
 	}
-	stream, err := c.NewStream(ctx, h.id, payloadB)
+	o := applyCallOptions(opts)
+	hs, hasHeaderStreamer := c.(headerStreamer)
+	var respHeader Metadata
+	base := func(ctx context.Context, payload []byte) (*Stream, error) {
+		if o.header != nil && hasHeaderStreamer {
+			st, hdr, err := hs.NewStreamWithMetadata(ctx, h.id, payload, o.header)
+			respHeader = hdr
+			return st, err
+		}
+		return c.NewStream(ctx, h.id, payload)
+	}
+	var ics []StreamClientInterceptor
+	if is, ok := c.(interceptedStreamer); ok {
+		ics = is.streamClientInterceptors()
+	}
+	info := &StreamClientInfo{HandlerID: h.id}
+	var chained StreamClientHandler = base
+	for i := len(ics) - 1; i >= 0; i-- {
+		ic, next := ics[i], chained
+		chained = func(ctx context.Context, payload []byte) (*Stream, error) {
+			return ic(ctx, payload, info, next)
+		}
+	}
+	var sh StatsHandler
+	if sr, ok := c.(statsRequester); ok {
+		sh = sr.statsHandler()
+	}
+	if sh != nil {
+		ctx = sh.TagRPC(ctx, &RPCTagInfo{HandlerID: h.id, Client: true})
+		sh.HandleRPC(ctx, Begin{Client: true, BeginTime: time.Now()})
+	}
+	// Only the initial dial is retried: once the stream is established the
+	// server may already be emitting responses, so a retry here can never
+	// duplicate work done by the handler itself.
+	stream, err := callWithRetry(ctx, h.retry, func() (*Stream, bool, error) {
+		st, err := chained(ctx, payloadB)
+		if err == nil {
+			return st, false, nil
+		}
+		return nil, errors.Is(err, ErrDisconnected), err
+	})
 	PutByteBuffer(payloadB)
 	if err != nil {
+		if sh != nil {
+			sh.HandleRPC(ctx, End{Client: true, EndTime: time.Now(), Error: err})
+		}
 		return nil, err
 	}
 
@@ -695,6 +886,9 @@ func (h *StreamTypeHandler[Payload, Req, Resp]) Call(ctx context.Context, c Stre
 					logger.LogOnceIf(ctx, err, err.Error())
 				}
 				h.PutRequest(req)
+				if sh != nil {
+					sh.HandleRPC(ctx, OutPayload{Client: true, Length: len(b), SentTime: time.Now()})
+				}
 				stream.Requests <- b
 			}
 		}()
@@ -702,7 +896,7 @@ func (h *StreamTypeHandler[Payload, Req, Resp]) Call(ctx context.Context, c Stre
 		close(stream.Requests)
 	}
 
-	return &TypedStream[Req, Resp]{responses: stream, newResp: h.NewResponse, Requests: reqT}, nil
+	return &TypedStream[Req, Resp]{responses: stream, newResp: h.NewResponse, Requests: reqT, header: respHeader}, nil
 }
 
 // NoPayload is a type that can be used for handlers that do not use a payload.
@@ -0,0 +1,149 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import "context"
+
+// UnaryServerInfo carries static information about a unary call
+// so interceptors can make routing or authorization decisions
+// without inspecting the payload.
+type UnaryServerInfo struct {
+	// HandlerID is the handler being invoked.
+	HandlerID HandlerID
+
+	// Subroute is the subroute requested, if any.
+	Subroute string
+}
+
+// UnaryClientInfo carries static information about an outgoing unary call.
+type UnaryClientInfo struct {
+	// HandlerID is the handler being called.
+	HandlerID HandlerID
+}
+
+// StreamServerInfo carries static information about an incoming stream.
+type StreamServerInfo struct {
+	// HandlerID is the handler being invoked.
+	HandlerID HandlerID
+
+	// Subroute is the subroute requested, if any.
+	Subroute string
+
+	// IsClientStream is true when the client sends input on the stream.
+	IsClientStream bool
+}
+
+// StreamClientInfo carries static information about an outgoing stream.
+type StreamClientInfo struct {
+	// HandlerID is the handler being called.
+	HandlerID HandlerID
+}
+
+// UnaryHandler is the final handler in a unary interceptor chain.
+// It has the shape of SingleHandlerFn with a leading context argument.
+// SingleHandler.Register and SingleHandler.Call build the chain for typed
+// handlers.
+type UnaryHandler func(ctx context.Context, payload []byte) ([]byte, *RemoteErr)
+
+// UnaryServerInterceptor can observe or modify a unary request/response
+// on the server before and after the handler runs. Implementations must
+// call next to continue the chain; not calling it short-circuits the call.
+type UnaryServerInterceptor func(ctx context.Context, id HandlerID, payload []byte, info *UnaryServerInfo, next UnaryHandler) ([]byte, *RemoteErr)
+
+// UnaryClientInterceptor can observe or modify a unary request/response
+// on the client before and after the call is sent. Implementations must
+// call next to continue the chain; not calling it short-circuits the call.
+type UnaryClientInterceptor func(ctx context.Context, id HandlerID, payload []byte, info *UnaryClientInfo, next UnaryHandler) ([]byte, *RemoteErr)
+
+// StreamServerHandler is the final handler in a stream interceptor chain.
+type StreamServerHandler func(ctx context.Context, payload []byte, in <-chan []byte, out chan<- []byte) *RemoteErr
+
+// StreamServerInterceptor can wrap the in/out channels of an incoming
+// stream, e.g. to observe every message, before handing off to the
+// next interceptor or the final handler. Implementations must call
+// next to continue the chain.
+type StreamServerInterceptor func(ctx context.Context, payload []byte, in <-chan []byte, out chan<- []byte, info *StreamServerInfo, next StreamServerHandler) *RemoteErr
+
+// StreamClientHandler is the final handler in a client stream interceptor chain.
+type StreamClientHandler func(ctx context.Context, payload []byte) (*Stream, error)
+
+// StreamClientInterceptor can wrap a client's view of a stream before
+// it is established. Implementations must call next to continue the chain.
+type StreamClientInterceptor func(ctx context.Context, payload []byte, info *StreamClientInfo, next StreamClientHandler) (*Stream, error)
+
+// chainUnaryServerInterceptors combines a slice of UnaryServerInterceptor into
+// one that invokes them in order, finally calling handle. A nil or empty
+// slice returns handle unchanged.
+func chainUnaryServerInterceptors(ics []UnaryServerInterceptor, id HandlerID, info *UnaryServerInfo, handle UnaryHandler) UnaryHandler {
+	if len(ics) == 0 {
+		return handle
+	}
+	return func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) {
+		return chainUnaryServer(ctx, ics, 0, id, payload, info, handle)
+	}
+}
+
+func chainUnaryServer(ctx context.Context, ics []UnaryServerInterceptor, idx int, id HandlerID, payload []byte, info *UnaryServerInfo, handle UnaryHandler) ([]byte, *RemoteErr) {
+	if idx == len(ics) {
+		return handle(ctx, payload)
+	}
+	return ics[idx](ctx, id, payload, info, func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) {
+		return chainUnaryServer(ctx, ics, idx+1, id, payload, info, handle)
+	})
+}
+
+// chainUnaryClientInterceptors combines a slice of UnaryClientInterceptor into
+// one that invokes them in order, finally calling call. A nil or empty
+// slice returns call unchanged.
+func chainUnaryClientInterceptors(ics []UnaryClientInterceptor, id HandlerID, info *UnaryClientInfo, call UnaryHandler) UnaryHandler {
+	if len(ics) == 0 {
+		return call
+	}
+	return func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) {
+		return chainUnaryClient(ctx, ics, 0, id, payload, info, call)
+	}
+}
+
+func chainUnaryClient(ctx context.Context, ics []UnaryClientInterceptor, idx int, id HandlerID, payload []byte, info *UnaryClientInfo, call UnaryHandler) ([]byte, *RemoteErr) {
+	if idx == len(ics) {
+		return call(ctx, payload)
+	}
+	return ics[idx](ctx, id, payload, info, func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) {
+		return chainUnaryClient(ctx, ics, idx+1, id, payload, info, call)
+	})
+}
+
+// chainStreamServerInterceptors combines a slice of StreamServerInterceptor
+// into one that invokes them in order, finally calling handle.
+func chainStreamServerInterceptors(ics []StreamServerInterceptor, info *StreamServerInfo, handle StreamServerHandler) StreamServerHandler {
+	if len(ics) == 0 {
+		return handle
+	}
+	return func(ctx context.Context, payload []byte, in <-chan []byte, out chan<- []byte) *RemoteErr {
+		return chainStreamServer(ctx, ics, 0, payload, in, out, info, handle)
+	}
+}
+
+func chainStreamServer(ctx context.Context, ics []StreamServerInterceptor, idx int, payload []byte, in <-chan []byte, out chan<- []byte, info *StreamServerInfo, handle StreamServerHandler) *RemoteErr {
+	if idx == len(ics) {
+		return handle(ctx, payload, in, out)
+	}
+	return ics[idx](ctx, payload, in, out, info, func(ctx context.Context, payload []byte, in <-chan []byte, out chan<- []byte) *RemoteErr {
+		return chainStreamServer(ctx, ics, idx+1, payload, in, out, info, handle)
+	})
+}
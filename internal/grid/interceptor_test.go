@@ -0,0 +1,124 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestChainUnaryServerInterceptorsRunsInOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) UnaryServerInterceptor {
+		return func(ctx context.Context, id HandlerID, payload []byte, info *UnaryServerInfo, next UnaryHandler) ([]byte, *RemoteErr) {
+			order = append(order, name+":before")
+			resp, err := next(ctx, payload)
+			order = append(order, name+":after")
+			return resp, err
+		}
+	}
+	handle := func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) {
+		order = append(order, "handle")
+		return payload, nil
+	}
+
+	chained := chainUnaryServerInterceptors([]UnaryServerInterceptor{mark("outer"), mark("inner")}, handlerTest, &UnaryServerInfo{}, handle)
+	if _, err := chained(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "outer:before,inner:before,handle,inner:after,outer:after"
+	if got := strings.Join(order, ","); got != want {
+		t.Fatalf("got order %q, want %q", got, want)
+	}
+}
+
+func TestChainUnaryServerInterceptorsEmptyReturnsHandleUnchanged(t *testing.T) {
+	handle := func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) { return payload, nil }
+	chained := chainUnaryServerInterceptors(nil, handlerTest, &UnaryServerInfo{}, handle)
+	resp, err := chained(context.Background(), []byte("x"))
+	if err != nil || string(resp) != "x" {
+		t.Fatalf("got (%q, %v), want (\"x\", nil)", resp, err)
+	}
+}
+
+func TestChainUnaryServerInterceptorsCanShortCircuit(t *testing.T) {
+	handleCalled := false
+	handle := func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) {
+		handleCalled = true
+		return payload, nil
+	}
+	denied := NewRemoteErrString("denied")
+	shortCircuit := func(ctx context.Context, id HandlerID, payload []byte, info *UnaryServerInfo, next UnaryHandler) ([]byte, *RemoteErr) {
+		return nil, denied
+	}
+
+	chained := chainUnaryServerInterceptors([]UnaryServerInterceptor{shortCircuit}, handlerTest, &UnaryServerInfo{}, handle)
+	_, err := chained(context.Background(), nil)
+	if err != denied {
+		t.Fatalf("got err %v, want the short-circuiting interceptor's error", err)
+	}
+	if handleCalled {
+		t.Fatal("handle should not run once an interceptor short-circuits the chain")
+	}
+}
+
+func TestChainUnaryClientInterceptorsRunsInOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) UnaryClientInterceptor {
+		return func(ctx context.Context, id HandlerID, payload []byte, info *UnaryClientInfo, next UnaryHandler) ([]byte, *RemoteErr) {
+			order = append(order, name)
+			return next(ctx, payload)
+		}
+	}
+	call := func(ctx context.Context, payload []byte) ([]byte, *RemoteErr) {
+		order = append(order, "call")
+		return payload, nil
+	}
+
+	chained := chainUnaryClientInterceptors([]UnaryClientInterceptor{mark("a"), mark("b")}, handlerTest, &UnaryClientInfo{}, call)
+	if _, err := chained(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "a,b,call" {
+		t.Fatalf("got order %q, want \"a,b,call\"", got)
+	}
+}
+
+func TestChainStreamServerInterceptorsRunsInOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) StreamServerInterceptor {
+		return func(ctx context.Context, payload []byte, in <-chan []byte, out chan<- []byte, info *StreamServerInfo, next StreamServerHandler) *RemoteErr {
+			order = append(order, name)
+			return next(ctx, payload, in, out)
+		}
+	}
+	handle := func(ctx context.Context, payload []byte, in <-chan []byte, out chan<- []byte) *RemoteErr {
+		order = append(order, "handle")
+		return nil
+	}
+
+	chained := chainStreamServerInterceptors([]StreamServerInterceptor{mark("a"), mark("b")}, &StreamServerInfo{}, handle)
+	if err := chained(context.Background(), nil, nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "a,b,handle" {
+		t.Fatalf("got order %q, want \"a,b,handle\"", got)
+	}
+}
@@ -0,0 +1,450 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// KeepaliveServerParams configures keepalive pings enforced by a Manager
+// acting as a server, modeled on gRPC's keepalive.ServerParameters.
+type KeepaliveServerParams struct {
+	// Time is the idle duration after which a PING is sent on a connection.
+	// 0 disables server-initiated pings.
+	Time time.Duration
+
+	// Timeout is how long the server waits for a PONG before closing the
+	// connection.
+	Timeout time.Duration
+
+	// MaxConnectionIdle is the duration after which an idle connection is
+	// sent a GOAWAY, allowing in-flight calls to drain before close.
+	// 0 disables idle eviction.
+	MaxConnectionIdle time.Duration
+
+	// MaxConnectionAge is the duration after which any connection is sent
+	// a GOAWAY regardless of activity, allowing in-flight calls to drain.
+	// 0 disables age-based eviction.
+	MaxConnectionAge time.Duration
+
+	// MinPingInterval is the minimum interval the server allows between
+	// client-initiated pings; a client pinging more frequently than this
+	// is considered abusive and its connection is closed to avoid ping
+	// floods.
+	MinPingInterval time.Duration
+}
+
+// KeepaliveClientParams configures keepalive pings sent by a Manager acting
+// as a client, modeled on gRPC's keepalive.ClientParameters.
+type KeepaliveClientParams struct {
+	// Time is the idle duration after which a PING is sent to the peer.
+	Time time.Duration
+
+	// Timeout is how long the client waits for a PONG before considering
+	// the connection dead.
+	Timeout time.Duration
+}
+
+// DefaultKeepaliveServerParams returns reasonable defaults: a ping every 30s
+// of inactivity, a 10s PONG timeout, and no idle/age based eviction.
+func DefaultKeepaliveServerParams() KeepaliveServerParams {
+	return KeepaliveServerParams{
+		Time:            30 * time.Second,
+		Timeout:         10 * time.Second,
+		MinPingInterval: 5 * time.Second,
+	}
+}
+
+// DefaultKeepaliveClientParams returns reasonable defaults: a ping every 30s
+// of inactivity and a 10s PONG timeout.
+func DefaultKeepaliveClientParams() KeepaliveClientParams {
+	return KeepaliveClientParams{
+		Time:    30 * time.Second,
+		Timeout: 10 * time.Second,
+	}
+}
+
+// WithKeepaliveServerParams configures the keepalive behavior a Manager
+// enforces on incoming connections.
+func WithKeepaliveServerParams(p KeepaliveServerParams) ManagerOption {
+	return func(m *Manager) {
+		m.keepaliveServer = p
+	}
+}
+
+// WithKeepaliveClientParams configures the keepalive pings a Manager sends
+// on outgoing connections.
+func WithKeepaliveClientParams(p KeepaliveClientParams) ManagerOption {
+	return func(m *Manager) {
+		m.keepaliveClient = p
+	}
+}
+
+// HandlerPing frames are not dispatched through the registered handlers
+// table; the connection layer intercepts them directly and, on decoding a
+// PONG that answers an outstanding PING, calls KeepaliveMonitor.RecordActivity
+// to clear the outstanding PING in the monitor below.
+
+// connLiveness reports the liveness of a single connection as observed by
+// the keepalive subsystem. HandlerLockRefresh and similar latency-sensitive
+// handlers can use this to fail fast instead of waiting out a TCP timeout
+// after a network partition.
+type connLiveness struct {
+	// lastActivity is the last time any frame (including pings) was seen.
+	lastActivity time.Time
+
+	// pingOutstanding is true between sending a PING and receiving its PONG.
+	pingOutstanding bool
+}
+
+// ConnState describes the liveness of a connection as seen by keepalive.
+type ConnState int
+
+const (
+	// ConnStateUnknown means no keepalive information is available, e.g.
+	// keepalive is disabled or the connection has not been established.
+	ConnStateUnknown ConnState = iota
+	// ConnStateAlive means the connection has responded within Timeout.
+	ConnStateAlive
+	// ConnStateStale means a PING is outstanding and has not yet timed out.
+	ConnStateStale
+	// ConnStateDead means a PING timed out and the connection is being closed.
+	ConnStateDead
+)
+
+func (c *connLiveness) state(now time.Time, timeout time.Duration) ConnState {
+	if c == nil {
+		return ConnStateUnknown
+	}
+	if !c.pingOutstanding {
+		return ConnStateAlive
+	}
+	if timeout > 0 && now.Sub(c.lastActivity) > timeout {
+		return ConnStateDead
+	}
+	return ConnStateStale
+}
+
+// pinger is implemented by a connection that KeepaliveMonitor can drive: send
+// a PING frame, and close the connection once it's given up waiting for a
+// PONG. A connection (connection.go) that wants keepalive enforcement
+// implements this and is handed to Manager.StartKeepalive; KeepaliveMonitor
+// itself never touches the wire.
+type pinger interface {
+	// Ping sends a PING frame. An error is treated the same as a PONG
+	// timeout: the connection is considered dead and closed.
+	Ping(ctx context.Context) error
+
+	// Close closes the connection because it stopped responding.
+	Close() error
+}
+
+// KeepaliveMonitor drives a single connection's PING/PONG liveness checks
+// according to a KeepaliveClientParams and reports its ConnState. Run does
+// the actual scheduling; RecordActivity is called by the connection layer
+// whenever a frame, including the PONG answering an outstanding PING, is
+// observed.
+type KeepaliveMonitor struct {
+	mu       sync.Mutex
+	liveness connLiveness
+}
+
+// NewKeepaliveMonitor returns a monitor with no PING outstanding.
+func NewKeepaliveMonitor() *KeepaliveMonitor {
+	return &KeepaliveMonitor{liveness: connLiveness{lastActivity: time.Now()}}
+}
+
+// RecordActivity marks that a frame was just observed on the connection,
+// clearing any outstanding PING so Run knows the peer is still alive.
+func (k *KeepaliveMonitor) RecordActivity(now time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.liveness.lastActivity = now
+	k.liveness.pingOutstanding = false
+}
+
+// State reports the connection's current liveness.
+func (k *KeepaliveMonitor) State(timeout time.Duration) ConnState {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.liveness.state(time.Now(), timeout)
+}
+
+// Run sends a PING through p after params.Time of inactivity, then closes p
+// if no RecordActivity call arrives within params.Timeout. It blocks until
+// ctx is done or p is closed after an unanswered PING; callers run it in its
+// own goroutine. Run returns immediately if params.Time is 0.
+func (k *KeepaliveMonitor) Run(ctx context.Context, p pinger, params KeepaliveClientParams) {
+	if params.Time <= 0 {
+		return
+	}
+	t := time.NewTimer(params.Time)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		k.mu.Lock()
+		idle := time.Since(k.liveness.lastActivity)
+		k.mu.Unlock()
+		if idle < params.Time {
+			t.Reset(params.Time - idle)
+			continue
+		}
+
+		k.mu.Lock()
+		k.liveness.pingOutstanding = true
+		// The PING itself counts as activity, so State measures Timeout
+		// from when it was sent.
+		k.liveness.lastActivity = time.Now()
+		k.mu.Unlock()
+		if err := p.Ping(ctx); err != nil {
+			p.Close()
+			return
+		}
+
+		if params.Timeout > 0 {
+			if !k.awaitPong(ctx, params.Timeout) {
+				p.Close()
+				return
+			}
+		}
+		t.Reset(params.Time)
+	}
+}
+
+// awaitPong waits up to timeout for RecordActivity to clear pingOutstanding.
+// It reports false if the PING timed out without an answer.
+func (k *KeepaliveMonitor) awaitPong(ctx context.Context, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(timeout / 10)
+	defer poll.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case <-deadline.C:
+			k.mu.Lock()
+			outstanding := k.liveness.pingOutstanding
+			k.mu.Unlock()
+			return !outstanding
+		case <-poll.C:
+			k.mu.Lock()
+			outstanding := k.liveness.pingOutstanding
+			k.mu.Unlock()
+			if !outstanding {
+				return true
+			}
+		}
+	}
+}
+
+// StartKeepalive begins monitoring remote's connection p according to the
+// Manager's configured KeepaliveClientParams, stopping when ctx is done. A
+// connection calls this once, right after dialing, if keepalive client
+// params were configured with WithKeepaliveClientParams; it is a no-op
+// otherwise so ConnState keeps returning ConnStateUnknown.
+func (m *Manager) StartKeepalive(ctx context.Context, remote string, p pinger) {
+	if m.keepaliveClient.Time <= 0 {
+		return
+	}
+	mon := NewKeepaliveMonitor()
+	m.connMu.Lock()
+	if m.connLiveness == nil {
+		m.connLiveness = make(map[string]*KeepaliveMonitor)
+	}
+	m.connLiveness[remote] = mon
+	m.connMu.Unlock()
+	go mon.Run(ctx, p, m.keepaliveClient)
+}
+
+// ConnState returns the liveness of the connection to remote, so callers
+// like the lock manager can fail HandlerLockRefresh fast instead of waiting
+// out an OS-level TCP timeout after a network partition. It is
+// ConnStateUnknown until StartKeepalive has been called for remote.
+func (m *Manager) ConnState(remote string) ConnState {
+	m.connMu.RLock()
+	mon := m.connLiveness[remote]
+	m.connMu.RUnlock()
+	if mon == nil {
+		return ConnStateUnknown
+	}
+	return mon.State(m.keepaliveClient.Timeout)
+}
+
+// goAwayer is implemented by a connection (connection.go, not part of this
+// source snapshot) that server-side keepalive enforcement can act on: send a
+// PING, close outright on an unanswered PONG, or drain with a GOAWAY-style
+// shutdown once MaxConnectionIdle/MaxConnectionAge is exceeded.
+type goAwayer interface {
+	pinger
+
+	// GoAway notifies the peer the connection is being drained for reason
+	// and closes it once in-flight calls complete.
+	GoAway(ctx context.Context, reason string) error
+}
+
+// ServerKeepalive enforces a KeepaliveServerParams on a single incoming
+// connection: it schedules server-initiated PINGs after idle periods,
+// drains the connection with GoAway once MaxConnectionIdle or
+// MaxConnectionAge is exceeded, and flags clients that PING more often than
+// MinPingInterval so the connection layer can close them as abusive.
+//
+// Nothing in this package constructs or drives a ServerKeepalive today:
+// doing so for real requires a per-connection dispatch loop in
+// connection.go, which this snapshot doesn't have, the same gap flowWindow
+// documents in flowcontrol.go. WithKeepaliveServerParams and
+// Manager.ServerKeepaliveParams exist so that loop can be wired up without
+// further API changes once connection.go lands.
+type ServerKeepalive struct {
+	params KeepaliveServerParams
+
+	mu              sync.Mutex
+	lastActivity    time.Time
+	started         time.Time
+	pingOutstanding bool
+	pingSentAt      time.Time
+	lastClientPing  time.Time
+}
+
+// NewServerKeepalive creates a ServerKeepalive for a connection considered
+// active as of now.
+func NewServerKeepalive(params KeepaliveServerParams, now time.Time) *ServerKeepalive {
+	return &ServerKeepalive{params: params, lastActivity: now, started: now}
+}
+
+// RecordActivity marks that a frame, including a PONG answering an
+// outstanding PING, was just observed on the connection.
+func (k *ServerKeepalive) RecordActivity(now time.Time) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.lastActivity = now
+	k.pingOutstanding = false
+}
+
+// AllowClientPing reports whether a PING received from the client at now is
+// acceptable, enforcing MinPingInterval flood protection. A caller should
+// close the connection when this returns false. A zero MinPingInterval
+// disables the check.
+func (k *ServerKeepalive) AllowClientPing(now time.Time) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.params.MinPingInterval > 0 && !k.lastClientPing.IsZero() && now.Sub(k.lastClientPing) < k.params.MinPingInterval {
+		return false
+	}
+	k.lastClientPing = now
+	return true
+}
+
+// Run sends a PING through g after params.Time of connection inactivity,
+// closing g if no RecordActivity call arrives within params.Timeout, and
+// calls g.GoAway once MaxConnectionIdle or MaxConnectionAge is exceeded. It
+// blocks until ctx is done or g is closed or drained; callers run it in its
+// own goroutine. Run returns immediately if params.Time, MaxConnectionIdle
+// and MaxConnectionAge are all 0.
+func (k *ServerKeepalive) Run(ctx context.Context, g goAwayer) {
+	if k.params.Time <= 0 && k.params.MaxConnectionIdle <= 0 && k.params.MaxConnectionAge <= 0 {
+		return
+	}
+	tick := k.params.Time
+	if tick <= 0 {
+		tick = k.params.MaxConnectionIdle
+	}
+	if k.params.MaxConnectionAge > 0 && (tick <= 0 || k.params.MaxConnectionAge < tick) {
+		tick = k.params.MaxConnectionAge
+	}
+	t := time.NewTimer(tick)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		now := time.Now()
+		k.mu.Lock()
+		idle := now.Sub(k.lastActivity)
+		age := now.Sub(k.started)
+		k.mu.Unlock()
+
+		if k.params.MaxConnectionAge > 0 && age >= k.params.MaxConnectionAge {
+			g.GoAway(ctx, "max_connection_age")
+			return
+		}
+		if k.params.MaxConnectionIdle > 0 && idle >= k.params.MaxConnectionIdle {
+			g.GoAway(ctx, "max_connection_idle")
+			return
+		}
+		if k.params.Time > 0 && idle >= k.params.Time {
+			k.mu.Lock()
+			k.pingOutstanding = true
+			k.pingSentAt = now
+			k.mu.Unlock()
+			if err := g.Ping(ctx); err != nil {
+				g.Close()
+				return
+			}
+			if k.params.Timeout > 0 && !k.awaitPong(ctx, k.params.Timeout) {
+				g.Close()
+				return
+			}
+		}
+		t.Reset(tick)
+	}
+}
+
+// awaitPong waits up to timeout for RecordActivity to clear pingOutstanding.
+// It reports false if the PING timed out without an answer.
+func (k *ServerKeepalive) awaitPong(ctx context.Context, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(timeout / 10)
+	defer poll.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case <-deadline.C:
+			k.mu.Lock()
+			outstanding := k.pingOutstanding
+			k.mu.Unlock()
+			return !outstanding
+		case <-poll.C:
+			k.mu.Lock()
+			outstanding := k.pingOutstanding
+			k.mu.Unlock()
+			if !outstanding {
+				return true
+			}
+		}
+	}
+}
+
+// ServerKeepaliveParams returns the Manager's configured
+// KeepaliveServerParams, so a connection can construct a ServerKeepalive for
+// itself once connection.go is able to drive one.
+func (m *Manager) ServerKeepaliveParams() KeepaliveServerParams {
+	return m.keepaliveServer
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakePinger counts Ping/Close calls and optionally answers every PING
+// immediately by recording activity on the monitor under test.
+type fakePinger struct {
+	pings  atomic.Int32
+	closed atomic.Bool
+	answer func()
+}
+
+func (f *fakePinger) Ping(ctx context.Context) error {
+	f.pings.Add(1)
+	if f.answer != nil {
+		f.answer()
+	}
+	return nil
+}
+
+func (f *fakePinger) Close() error {
+	f.closed.Store(true)
+	return nil
+}
+
+func TestConnLivenessState(t *testing.T) {
+	now := time.Now()
+	var unset *connLiveness
+	if got := unset.state(now, time.Second); got != ConnStateUnknown {
+		t.Fatalf("nil connLiveness: got %v, want ConnStateUnknown", got)
+	}
+	alive := &connLiveness{lastActivity: now}
+	if got := alive.state(now, time.Second); got != ConnStateAlive {
+		t.Fatalf("no ping outstanding: got %v, want ConnStateAlive", got)
+	}
+	stale := &connLiveness{lastActivity: now, pingOutstanding: true}
+	if got := stale.state(now, time.Second); got != ConnStateStale {
+		t.Fatalf("ping just sent: got %v, want ConnStateStale", got)
+	}
+	dead := &connLiveness{lastActivity: now.Add(-2 * time.Second), pingOutstanding: true}
+	if got := dead.state(now, time.Second); got != ConnStateDead {
+		t.Fatalf("ping outstanding past timeout: got %v, want ConnStateDead", got)
+	}
+}
+
+func TestKeepaliveMonitorPingsAfterIdleAndSurvivesPong(t *testing.T) {
+	mon := NewKeepaliveMonitor()
+	f := &fakePinger{answer: func() { mon.RecordActivity(time.Now()) }}
+	params := KeepaliveClientParams{Time: 5 * time.Millisecond, Timeout: 50 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 40*time.Millisecond)
+	defer cancel()
+	mon.Run(ctx, f, params)
+
+	if f.pings.Load() == 0 {
+		t.Fatal("expected at least one PING after the idle interval elapsed")
+	}
+	if f.closed.Load() {
+		t.Fatal("connection should not be closed when every PING is answered")
+	}
+	if got := mon.State(params.Timeout); got != ConnStateAlive {
+		t.Fatalf("State() = %v, want ConnStateAlive", got)
+	}
+}
+
+func TestKeepaliveMonitorClosesOnUnansweredPing(t *testing.T) {
+	mon := NewKeepaliveMonitor()
+	f := &fakePinger{} // never answers
+	params := KeepaliveClientParams{Time: 2 * time.Millisecond, Timeout: 5 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	mon.Run(ctx, f, params)
+
+	if !f.closed.Load() {
+		t.Fatal("expected Close to be called after the PONG timeout elapsed")
+	}
+	if f.pings.Load() != 1 {
+		t.Fatalf("expected exactly 1 PING before giving up, got %d", f.pings.Load())
+	}
+}
+
+func TestManagerConnStateUnknownUntilStarted(t *testing.T) {
+	m := &Manager{}
+	if got := m.ConnState("remote-1"); got != ConnStateUnknown {
+		t.Fatalf("ConnState before StartKeepalive = %v, want ConnStateUnknown", got)
+	}
+}
+
+// fakeGoAwayer counts Ping/Close/GoAway calls and optionally answers every
+// PING immediately by recording activity on the ServerKeepalive under test.
+type fakeGoAwayer struct {
+	pings        atomic.Int32
+	closed       atomic.Bool
+	goAway       atomic.Bool
+	goAwayReason string
+	answer       func()
+}
+
+func (f *fakeGoAwayer) Ping(ctx context.Context) error {
+	f.pings.Add(1)
+	if f.answer != nil {
+		f.answer()
+	}
+	return nil
+}
+
+func (f *fakeGoAwayer) Close() error {
+	f.closed.Store(true)
+	return nil
+}
+
+func (f *fakeGoAwayer) GoAway(ctx context.Context, reason string) error {
+	f.goAway.Store(true)
+	f.goAwayReason = reason
+	return nil
+}
+
+func TestServerKeepaliveAllowClientPingEnforcesMinInterval(t *testing.T) {
+	now := time.Now()
+	k := NewServerKeepalive(KeepaliveServerParams{MinPingInterval: 10 * time.Millisecond}, now)
+	if !k.AllowClientPing(now) {
+		t.Fatal("the first client PING should always be allowed")
+	}
+	if k.AllowClientPing(now.Add(time.Millisecond)) {
+		t.Fatal("a PING before MinPingInterval elapsed should be rejected as abusive")
+	}
+	if !k.AllowClientPing(now.Add(20 * time.Millisecond)) {
+		t.Fatal("a PING after MinPingInterval elapsed should be allowed")
+	}
+}
+
+func TestServerKeepaliveRunPingsAfterIdleAndSurvivesPong(t *testing.T) {
+	k := NewServerKeepalive(KeepaliveServerParams{Time: 5 * time.Millisecond, Timeout: 50 * time.Millisecond}, time.Now())
+	g := &fakeGoAwayer{answer: func() { k.RecordActivity(time.Now()) }}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 40*time.Millisecond)
+	defer cancel()
+	k.Run(ctx, g)
+
+	if g.pings.Load() == 0 {
+		t.Fatal("expected at least one server-initiated PING after the idle interval elapsed")
+	}
+	if g.closed.Load() || g.goAway.Load() {
+		t.Fatal("connection should not be closed or drained when every PING is answered")
+	}
+}
+
+func TestServerKeepaliveRunClosesOnUnansweredPing(t *testing.T) {
+	k := NewServerKeepalive(KeepaliveServerParams{Time: 2 * time.Millisecond, Timeout: 5 * time.Millisecond}, time.Now())
+	g := &fakeGoAwayer{} // never answers
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	k.Run(ctx, g)
+
+	if !g.closed.Load() {
+		t.Fatal("expected Close to be called after the PONG timeout elapsed")
+	}
+	if g.pings.Load() != 1 {
+		t.Fatalf("expected exactly 1 PING before giving up, got %d", g.pings.Load())
+	}
+}
+
+func TestServerKeepaliveRunGoesAwayOnMaxConnectionIdle(t *testing.T) {
+	k := NewServerKeepalive(KeepaliveServerParams{MaxConnectionIdle: 5 * time.Millisecond}, time.Now())
+	g := &fakeGoAwayer{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	k.Run(ctx, g)
+
+	if !g.goAway.Load() {
+		t.Fatal("expected GoAway to be called once MaxConnectionIdle elapsed")
+	}
+	if g.goAwayReason != "max_connection_idle" {
+		t.Fatalf("GoAway reason = %q, want %q", g.goAwayReason, "max_connection_idle")
+	}
+}
+
+func TestServerKeepaliveRunGoesAwayOnMaxConnectionAge(t *testing.T) {
+	k := NewServerKeepalive(KeepaliveServerParams{MaxConnectionAge: 5 * time.Millisecond}, time.Now())
+	g := &fakeGoAwayer{answer: func() { k.RecordActivity(time.Now()) }}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	k.Run(ctx, g)
+
+	if !g.goAway.Load() {
+		t.Fatal("expected GoAway to be called once MaxConnectionAge elapsed, regardless of activity")
+	}
+	if g.goAwayReason != "max_connection_age" {
+		t.Fatalf("GoAway reason = %q, want %q", g.goAwayReason, "max_connection_age")
+	}
+}
+
+func TestServerKeepaliveRunNoopWhenEverythingDisabled(t *testing.T) {
+	k := NewServerKeepalive(KeepaliveServerParams{}, time.Now())
+	g := &fakeGoAwayer{}
+
+	done := make(chan struct{})
+	go func() {
+		k.Run(context.Background(), g)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run should return immediately when Time, MaxConnectionIdle and MaxConnectionAge are all 0")
+	}
+}
+
+func TestManagerServerKeepaliveParams(t *testing.T) {
+	want := DefaultKeepaliveServerParams()
+	m := &Manager{}
+	WithKeepaliveServerParams(want)(m)
+	if got := m.ServerKeepaliveParams(); got != want {
+		t.Fatalf("ServerKeepaliveParams() = %+v, want %+v", got, want)
+	}
+}
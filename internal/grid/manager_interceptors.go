@@ -0,0 +1,96 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+// ManagerOption configures a Manager at construction time.
+// See WithUnaryServerInterceptors, WithUnaryClientInterceptors,
+// WithStreamServerInterceptors and WithStreamClientInterceptors.
+type ManagerOption func(*Manager)
+
+// WithUnaryServerInterceptors registers interceptors to run around every
+// incoming unary call, in the order given. Interceptors are appended to
+// any previously configured with this option.
+func WithUnaryServerInterceptors(ics ...UnaryServerInterceptor) ManagerOption {
+	return func(m *Manager) {
+		m.unaryServerInts = append(m.unaryServerInts, ics...)
+	}
+}
+
+// WithUnaryClientInterceptors registers interceptors to run around every
+// outgoing unary call, in the order given. Interceptors are appended to
+// any previously configured with this option.
+func WithUnaryClientInterceptors(ics ...UnaryClientInterceptor) ManagerOption {
+	return func(m *Manager) {
+		m.unaryClientInts = append(m.unaryClientInts, ics...)
+	}
+}
+
+// WithStreamServerInterceptors registers interceptors to run around every
+// incoming stream, in the order given. Interceptors are appended to any
+// previously configured with this option.
+func WithStreamServerInterceptors(ics ...StreamServerInterceptor) ManagerOption {
+	return func(m *Manager) {
+		m.streamServerInts = append(m.streamServerInts, ics...)
+	}
+}
+
+// WithStreamClientInterceptors registers interceptors to run around every
+// outgoing stream, in the order given. Interceptors are appended to any
+// previously configured with this option.
+func WithStreamClientInterceptors(ics ...StreamClientInterceptor) ManagerOption {
+	return func(m *Manager) {
+		m.streamClientInts = append(m.streamClientInts, ics...)
+	}
+}
+
+// unaryServerInterceptors returns the configured unary server interceptor
+// chain, or nil if none were registered.
+func (m *Manager) unaryServerInterceptors() []UnaryServerInterceptor {
+	if m == nil {
+		return nil
+	}
+	return m.unaryServerInts
+}
+
+// unaryClientInterceptors returns the configured unary client interceptor
+// chain, or nil if none were registered. It satisfies interceptedRequester
+// so SingleHandler.Call can chain them around a remote call.
+func (m *Manager) unaryClientInterceptors() []UnaryClientInterceptor {
+	if m == nil {
+		return nil
+	}
+	return m.unaryClientInts
+}
+
+// streamServerInterceptors returns the configured stream server interceptor
+// chain, or nil if none were registered.
+func (m *Manager) streamServerInterceptors() []StreamServerInterceptor {
+	if m == nil {
+		return nil
+	}
+	return m.streamServerInts
+}
+
+// streamClientInterceptors returns the configured stream client interceptor
+// chain, or nil if none were registered.
+func (m *Manager) streamClientInterceptors() []StreamClientInterceptor {
+	if m == nil {
+		return nil
+	}
+	return m.streamClientInts
+}
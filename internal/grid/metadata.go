@@ -0,0 +1,108 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import "context"
+
+// Metadata is a set of request-scoped key/value pairs that can be attached
+// to a call as a header (sent with the initial request) or a trailer (sent
+// by the server at the end of a unary call, even when the call returns an
+// error; streams carry no trailer).
+// It is used for out-of-band data like tenant IDs, W3C traceparent, client
+// version, or deadline hints, without redefining every handler's Req/Resp.
+type Metadata map[string][]string
+
+// Get returns the first value associated with key, or "" if there is none.
+func (m Metadata) Get(key string) string {
+	v := m[key]
+	if len(v) == 0 {
+		return ""
+	}
+	return v[0]
+}
+
+// Set sets key to a single value, replacing any existing values.
+func (m Metadata) Set(key, value string) {
+	m[key] = []string{value}
+}
+
+// Add appends value to the values already associated with key.
+func (m Metadata) Add(key, value string) {
+	m[key] = append(m[key], value)
+}
+
+// Clone returns a deep copy of m.
+func (m Metadata) Clone() Metadata {
+	if m == nil {
+		return nil
+	}
+	c := make(Metadata, len(m))
+	for k, v := range m {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// headerRequester is implemented by a Requester that can carry header
+// Metadata alongside the raw request bytes and hand back trailer Metadata
+// sent by the remote at end-of-call, even when err is non-nil. Call checks
+// for this with a type assertion, the same way it already does for
+// interceptedRequester and statsRequester, so a header is only ever sent to
+// a Requester that actually knows how to frame it.
+type headerRequester interface {
+	RequestWithMetadata(ctx context.Context, h HandlerID, req []byte, header Metadata) (resp []byte, trailer Metadata, err error)
+}
+
+// headerStreamer is the Streamer equivalent of headerRequester: it can
+// attach header Metadata to a new stream and reports the header the remote
+// sent back, which TypedStream.Header exposes to the caller.
+type headerStreamer interface {
+	NewStreamWithMetadata(ctx context.Context, h HandlerID, payload []byte, header Metadata) (st *Stream, respHeader Metadata, err error)
+}
+
+// CallOption configures an individual unary or stream call.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	header  Metadata
+	trailer *Metadata
+}
+
+// WithHeader attaches md as the header metadata sent with the call.
+func WithHeader(md Metadata) CallOption {
+	return func(o *callOptions) {
+		o.header = md
+	}
+}
+
+// WithTrailer arranges for the trailer Metadata sent by the remote at
+// end-of-call to be written into *md once the call returns, including when
+// it returns an error. md must be non-nil.
+func WithTrailer(md *Metadata) CallOption {
+	return func(o *callOptions) {
+		o.trailer = md
+	}
+}
+
+func applyCallOptions(opts []CallOption) callOptions {
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
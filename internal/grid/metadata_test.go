@@ -0,0 +1,92 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"context"
+	"testing"
+)
+
+// fakeHeaderRequester implements both Requester and headerRequester, so
+// tests can tell which path SingleHandler.Call actually took.
+type fakeHeaderRequester struct {
+	gotHeader   Metadata
+	trailer     Metadata
+	plainCalled bool
+}
+
+func (f *fakeHeaderRequester) Request(ctx context.Context, h HandlerID, req []byte) ([]byte, error) {
+	f.plainCalled = true
+	return nil, nil
+}
+
+func (f *fakeHeaderRequester) RequestWithMetadata(ctx context.Context, h HandlerID, req []byte, header Metadata) ([]byte, Metadata, error) {
+	f.gotHeader = header
+	return nil, f.trailer, nil
+}
+
+func TestSingleHandlerCallSendsHeaderAndReceivesTrailer(t *testing.T) {
+	h := NewSingleHandler[NoPayload, NoPayload](handlerTest, NewNoPayload, NewNoPayload)
+	f := &fakeHeaderRequester{trailer: Metadata{"x-served-by": {"node-1"}}}
+	header := Metadata{"x-tenant": {"acme"}}
+	var trailer Metadata
+
+	_, err := h.Call(context.Background(), f, NewNoPayload(), WithHeader(header), WithTrailer(&trailer))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.plainCalled {
+		t.Fatal("Call used Request instead of RequestWithMetadata even though a header was set and the Requester implements headerRequester")
+	}
+	if got := f.gotHeader.Get("x-tenant"); got != "acme" {
+		t.Fatalf("RequestWithMetadata did not receive the header: got %q", got)
+	}
+	if got := trailer.Get("x-served-by"); got != "node-1" {
+		t.Fatalf("WithTrailer did not receive the remote's trailer: got %q", got)
+	}
+}
+
+func TestSingleHandlerCallWithTrailerOnlyStillReceivesTrailer(t *testing.T) {
+	h := NewSingleHandler[NoPayload, NoPayload](handlerTest, NewNoPayload, NewNoPayload)
+	f := &fakeHeaderRequester{trailer: Metadata{"x-served-by": {"node-1"}}}
+	var trailer Metadata
+
+	_, err := h.Call(context.Background(), f, NewNoPayload(), WithTrailer(&trailer))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.plainCalled {
+		t.Fatal("Call used Request instead of RequestWithMetadata even though WithTrailer was set and the Requester implements headerRequester")
+	}
+	if got := trailer.Get("x-served-by"); got != "node-1" {
+		t.Fatalf("WithTrailer without WithHeader did not receive the remote's trailer: got %q", got)
+	}
+}
+
+func TestSingleHandlerCallWithoutHeaderUsesPlainRequest(t *testing.T) {
+	h := NewSingleHandler[NoPayload, NoPayload](handlerTest, NewNoPayload, NewNoPayload)
+	f := &fakeHeaderRequester{}
+
+	_, err := h.Call(context.Background(), f, NewNoPayload())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !f.plainCalled {
+		t.Fatal("Call should fall back to Request when no header option is given")
+	}
+}
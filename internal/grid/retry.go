@@ -0,0 +1,155 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls automatic retries of a call on transient errors.
+// The zero value disables retries (MaxAttempts 0 means "try once, no retry").
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first.
+	// 0 or 1 disables retries.
+	MaxAttempts int
+
+	// Base is the backoff duration used for the first retry.
+	Base time.Duration
+
+	// Max is the maximum backoff duration between retries.
+	Max time.Duration
+
+	// Retryable reports whether err should be retried.
+	// If nil, IsRetryableError is used.
+	Retryable func(err error) bool
+}
+
+// defaultRetryPolicy never retries. Handlers opt in with WithRetryPolicy.
+var defaultRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
+// ErrDisconnected is returned when a call fails because the underlying
+// connection was lost before a response (or, for streams, the initial
+// handshake) was observed.
+var ErrDisconnected = errors.New("grid: connection disconnected before response")
+
+// IsRetryableError reports whether err represents a transient failure that
+// is safe to retry: a lost connection or a Status with CodeUnavailable or
+// CodeResourceExhausted.
+// It does not consider whether the request may have been observed by the
+// server; callers must gate that separately (see callWithRetry's
+// notObserved result) to avoid duplicating non-idempotent writes.
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrDisconnected) {
+		return true
+	}
+	if s, ok := StatusFromError(err); ok {
+		switch s.Code {
+		case CodeUnavailable, CodeResourceExhausted:
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the duration to wait before the given retry attempt
+// (0-indexed: attempt 0 is the delay before the first retry), computed as
+// min(max, base*2^attempt) multiplied by a uniform [0,1) jitter factor, i.e.
+// "full jitter" as described in the AWS Architecture Blog's backoff post.
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := base
+	for i := 0; i < attempt; i++ {
+		d *= 2
+		if d >= max {
+			d = max
+			break
+		}
+	}
+	if d > max {
+		d = max
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// WithRetryPolicy sets the retry policy used by Call and returns h for
+// chaining. Retries only occur for attempts where the request is known not
+// to have reached the server yet (the connection failed before any response
+// bytes were observed), so non-idempotent handlers like RenameData and
+// DeleteVersion are never replayed.
+func (h *SingleHandler[Req, Resp]) WithRetryPolicy(maxAttempts int, base, max time.Duration, retryable func(error) bool) *SingleHandler[Req, Resp] {
+	h.retry = RetryPolicy{MaxAttempts: maxAttempts, Base: base, Max: max, Retryable: retryable}
+	return h
+}
+
+// WithRetryPolicy sets the retry policy used when dialing the stream and
+// returns h for chaining. Only the initial stream creation is retried;
+// once the server has begun sending responses the call is never replayed.
+func (h *StreamTypeHandler[Payload, Req, Resp]) WithRetryPolicy(maxAttempts int, base, max time.Duration, retryable func(error) bool) *StreamTypeHandler[Payload, Req, Resp] {
+	h.retry = RetryPolicy{MaxAttempts: maxAttempts, Base: base, Max: max, Retryable: retryable}
+	return h
+}
+
+func (p RetryPolicy) isRetryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryableError(err)
+}
+
+// callWithRetry runs attempt repeatedly according to p until it succeeds,
+// the context is done, attempts are exhausted, or the error is not
+// retryable. attempt must report notObserved=true only when it is certain
+// the server has not observed the request, so retries never duplicate writes.
+func callWithRetry[T any](ctx context.Context, p RetryPolicy, attempt func() (T, bool, error)) (res T, err error) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	for i := 0; ; i++ {
+		var notObserved bool
+		res, notObserved, err = attempt()
+		if err == nil {
+			return res, nil
+		}
+		if i+1 >= maxAttempts || !notObserved || !p.isRetryable(err) {
+			return res, err
+		}
+		d := backoff(i, p.Base, p.Max)
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return res, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	base := 10 * time.Millisecond
+	max := 100 * time.Millisecond
+	for attempt := 0; attempt < 10; attempt++ {
+		for i := 0; i < 20; i++ {
+			d := backoff(attempt, base, max)
+			if d < 0 {
+				t.Fatalf("attempt %d: backoff returned negative duration %v", attempt, d)
+			}
+			if d > max {
+				t.Fatalf("attempt %d: backoff %v exceeds max %v", attempt, d, max)
+			}
+		}
+	}
+}
+
+func TestBackoffGrows(t *testing.T) {
+	base := 10 * time.Millisecond
+	max := time.Second
+	// With full jitter the individual samples are random, but every sample
+	// for a given attempt must stay below that attempt's ceiling
+	// (base*2^attempt, capped at max), and the ceiling must never shrink as
+	// attempt grows.
+	prevCeil := time.Duration(0)
+	for attempt := 0; attempt < 8; attempt++ {
+		ceil := base
+		for i := 0; i < attempt; i++ {
+			ceil *= 2
+			if ceil >= max {
+				ceil = max
+				break
+			}
+		}
+		if ceil < prevCeil {
+			t.Fatalf("attempt %d: ceiling %v should not shrink from %v", attempt, ceil, prevCeil)
+		}
+		for i := 0; i < 20; i++ {
+			if d := backoff(attempt, base, max); d >= ceil {
+				t.Fatalf("attempt %d: backoff %v not below ceiling %v", attempt, d, ceil)
+			}
+		}
+		prevCeil = ceil
+	}
+}
+
+func TestCallWithRetryOnlyRetriesWhenNotObserved(t *testing.T) {
+	attempts := 0
+	_, err := callWithRetry(context.Background(), RetryPolicy{MaxAttempts: 3, Base: time.Millisecond, Max: time.Millisecond}, func() (int, bool, error) {
+		attempts++
+		return 0, false, ErrDisconnected // not yet observed=false: must not retry
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if attempts != 1 {
+		t.Fatalf("expected exactly 1 attempt when notObserved=false, got %d", attempts)
+	}
+}
+
+func TestCallWithRetryRetriesWhenNotObserved(t *testing.T) {
+	attempts := 0
+	_, err := callWithRetry(context.Background(), RetryPolicy{MaxAttempts: 3, Base: time.Millisecond, Max: time.Millisecond}, func() (int, bool, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, true, ErrDisconnected
+		}
+		return 42, false, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestCallWithRetryStopsOnNonRetryableError(t *testing.T) {
+	attempts := 0
+	wantErr := errors.New("permanent")
+	_, err := callWithRetry(context.Background(), RetryPolicy{MaxAttempts: 5, Base: time.Millisecond, Max: time.Millisecond}, func() (int, bool, error) {
+		attempts++
+		return 0, true, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected exactly 1 attempt for a non-retryable error, got %d", attempts)
+	}
+}
+
+// TestRemoteErrIsMatchesSentinelByMessage guards against regressing to a
+// RemoteErr.Is that only matches other *RemoteErr values: SingleHandler.Call
+// wraps every error from a Requester into a *RemoteErr before it reaches
+// callWithRetry, so errors.Is(wrapped, ErrDisconnected) must still succeed or
+// a disconnected unary call would silently never retry.
+func TestRemoteErrIsMatchesSentinelByMessage(t *testing.T) {
+	if !errors.Is(NewRemoteErr(ErrDisconnected), ErrDisconnected) {
+		t.Fatal("a RemoteErr wrapping ErrDisconnected should still match the sentinel via errors.Is")
+	}
+	if !errors.Is(ErrDisconnected, ErrDisconnected) {
+		t.Fatal("errors.Is should match the sentinel against itself")
+	}
+	if errors.Is(NewRemoteErr(errors.New("some other error")), ErrDisconnected) {
+		t.Fatal("a RemoteErr wrapping an unrelated error should not match ErrDisconnected")
+	}
+}
+
+type alwaysDisconnectedRequester struct {
+	attempts int
+}
+
+func (r *alwaysDisconnectedRequester) Request(ctx context.Context, id HandlerID, payload []byte) ([]byte, error) {
+	r.attempts++
+	return nil, ErrDisconnected
+}
+
+// TestSingleHandlerCallRetriesOnDisconnect is the end-to-end regression test
+// for the retry-gating bug: a Requester that always fails with
+// ErrDisconnected before any response bytes were observed must be retried up
+// to MaxAttempts times, not given up on after the first attempt.
+func TestSingleHandlerCallRetriesOnDisconnect(t *testing.T) {
+	h := NewSingleHandler[NoPayload, NoPayload](handlerTest, NewNoPayload, NewNoPayload).
+		WithRetryPolicy(3, time.Millisecond, time.Millisecond, nil)
+	c := &alwaysDisconnectedRequester{}
+
+	_, err := h.Call(context.Background(), c, NewNoPayload())
+	if !errors.Is(err, ErrDisconnected) {
+		t.Fatalf("expected the final error to be ErrDisconnected, got %v", err)
+	}
+	if c.attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", c.attempts)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"context"
+	"time"
+)
+
+// RPCTagInfo carries static information about an RPC passed to
+// StatsHandler.TagRPC before the call begins, so the handler can attach
+// per-call state (e.g. a span) to the context.
+type RPCTagInfo struct {
+	// HandlerID is the handler being invoked or called.
+	HandlerID HandlerID
+
+	// Subroute is the subroute, if any.
+	Subroute string
+
+	// Client is true for an outgoing call, false for an incoming one.
+	Client bool
+}
+
+// RPCStats is implemented by the concrete event types Begin, InPayload,
+// OutPayload and End, passed to StatsHandler.HandleRPC as the RPC
+// progresses.
+type RPCStats interface {
+	// IsClient returns true if this stats event is from the client side.
+	IsClient() bool
+}
+
+// Begin is emitted when an RPC starts, before the request is sent or
+// before a server handler is invoked.
+type Begin struct {
+	Client    bool
+	BeginTime time.Time
+}
+
+// IsClient implements RPCStats.
+func (s Begin) IsClient() bool { return s.Client }
+
+// InPayload is emitted for every message received by a unary call or by a
+// server stream handler. Responses a client reads from a TypedStream do not
+// emit it.
+type InPayload struct {
+	Client   bool
+	Length   int
+	RecvTime time.Time
+}
+
+// IsClient implements RPCStats.
+func (s InPayload) IsClient() bool { return s.Client }
+
+// OutPayload is emitted for every message sent, unary or streaming.
+type OutPayload struct {
+	Client   bool
+	Length   int
+	SentTime time.Time
+}
+
+// IsClient implements RPCStats.
+func (s OutPayload) IsClient() bool { return s.Client }
+
+// End is emitted when an RPC completes, successfully or not. On the client
+// side of a stream, End is emitted only when the dial fails; an established
+// client stream emits no End.
+type End struct {
+	Client  bool
+	EndTime time.Time
+	Error   error
+}
+
+// IsClient implements RPCStats.
+func (s End) IsClient() bool { return s.Client }
+
+// ConnTagInfo carries static information about a connection passed to
+// StatsHandler.TagConn when it is established.
+type ConnTagInfo struct {
+	// RemoteName identifies the remote peer.
+	RemoteName string
+
+	// Client is true if this side dialed the connection.
+	Client bool
+}
+
+// ConnStats is implemented by ConnBegin and ConnEnd, passed to
+// StatsHandler.HandleConn as a connection's lifecycle progresses.
+type ConnStats interface {
+	IsClient() bool
+}
+
+// ConnBegin is emitted when a connection is established.
+type ConnBegin struct{ Client bool }
+
+// IsClient implements ConnStats.
+func (s ConnBegin) IsClient() bool { return s.Client }
+
+// ConnEnd is emitted when a connection is closed.
+type ConnEnd struct{ Client bool }
+
+// IsClient implements ConnStats.
+func (s ConnEnd) IsClient() bool { return s.Client }
+
+// StatsHandler is a single hook for observing the full lifecycle of RPCs
+// and connections, so operators can emit Prometheus counters (e.g.
+// grid_rpc_started_total, grid_rpc_handled_total{code=...}, grid_msg_bytes)
+// and OpenTelemetry spans with accurate send/receive timestamps, instead of
+// relying on logger.LogOnceIf calls sprinkled inside the framework.
+//
+// A nil StatsHandler on a Manager is a no-op: every call site in this
+// package checks for nil before invoking it, so the hot path for lock
+// refresh is unaffected when no StatsHandler is configured.
+type StatsHandler interface {
+	// TagRPC is called before an RPC begins, client or server side.
+	// The returned context is used for the remainder of the RPC, so
+	// implementations can attach state to it (e.g. a span) and retrieve it
+	// in later HandleRPC calls.
+	TagRPC(ctx context.Context, info *RPCTagInfo) context.Context
+
+	// HandleRPC processes an RPC stats event. It may be called concurrently
+	// for streaming RPCs, once per message.
+	HandleRPC(ctx context.Context, stats RPCStats)
+
+	// TagConn is called when a connection is established.
+	TagConn(ctx context.Context, info *ConnTagInfo) context.Context
+
+	// HandleConn processes a connection stats event.
+	HandleConn(ctx context.Context, stats ConnStats)
+}
+
+// WithStatsHandler registers h as the Manager's StatsHandler.
+func WithStatsHandler(h StatsHandler) ManagerOption {
+	return func(m *Manager) {
+		m.statsH = h
+	}
+}
+
+// stats returns the Manager's configured StatsHandler, or nil.
+func (m *Manager) stats() StatsHandler {
+	if m == nil {
+		return nil
+	}
+	return m.statsH
+}
+
+// statsHandler implements statsRequester so code holding a *Manager as a
+// Requester/Streamer can reach its configured StatsHandler.
+func (m *Manager) statsHandler() StatsHandler {
+	return m.stats()
+}
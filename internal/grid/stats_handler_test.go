@@ -0,0 +1,145 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeStatsHandler records every RPCStats event it sees, in order, so tests
+// can assert both which events fired and their Client/Length fields.
+type fakeStatsHandler struct {
+	events []RPCStats
+}
+
+func (f *fakeStatsHandler) TagRPC(ctx context.Context, info *RPCTagInfo) context.Context { return ctx }
+func (f *fakeStatsHandler) HandleRPC(ctx context.Context, stats RPCStats) {
+	f.events = append(f.events, stats)
+}
+func (f *fakeStatsHandler) TagConn(ctx context.Context, info *ConnTagInfo) context.Context {
+	return ctx
+}
+func (f *fakeStatsHandler) HandleConn(ctx context.Context, stats ConnStats) {}
+
+// fakeStatsRequester implements Requester and statsRequester, so
+// SingleHandler.Call drives sh the same way a real connection would.
+type fakeStatsRequester struct {
+	sh   StatsHandler
+	resp []byte
+	err  error
+}
+
+func (f *fakeStatsRequester) Request(ctx context.Context, h HandlerID, req []byte) ([]byte, error) {
+	return f.resp, f.err
+}
+
+func (f *fakeStatsRequester) statsHandler() StatsHandler { return f.sh }
+
+func TestSingleHandlerCallFiresStatsInOrder(t *testing.T) {
+	sh := &fakeStatsHandler{}
+	cannedResp := []byte("x")
+	c := &fakeStatsRequester{sh: sh, resp: cannedResp}
+	h := NewSingleHandler[NoPayload, NoPayload](handlerTest, NewNoPayload, NewNoPayload)
+
+	if _, err := h.Call(context.Background(), c, NewNoPayload()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sh.events) != 4 {
+		t.Fatalf("expected 4 events (Begin, OutPayload, InPayload, End), got %d: %#v", len(sh.events), sh.events)
+	}
+	begin, ok := sh.events[0].(Begin)
+	if !ok || !begin.Client {
+		t.Fatalf("expected first event to be a client Begin, got %#v", sh.events[0])
+	}
+	if out, ok := sh.events[1].(OutPayload); !ok || !out.Client {
+		t.Fatalf("expected second event to be a client OutPayload, got %#v", sh.events[1])
+	}
+	if in, ok := sh.events[2].(InPayload); !ok || !in.Client || in.Length != len(cannedResp) {
+		t.Fatalf("expected third event to be a client InPayload of length %d, got %#v", len(cannedResp), sh.events[2])
+	}
+	end, ok := sh.events[3].(End)
+	if !ok || !end.Client || end.Error != nil {
+		t.Fatalf("expected last event to be a client End with no error, got %#v", sh.events[3])
+	}
+}
+
+func TestSingleHandlerCallSkipsInPayloadOnError(t *testing.T) {
+	sh := &fakeStatsHandler{}
+	c := &fakeStatsRequester{sh: sh, err: errors.New("boom")}
+	h := NewSingleHandler[NoPayload, NoPayload](handlerTest, NewNoPayload, NewNoPayload)
+
+	if _, err := h.Call(context.Background(), c, NewNoPayload()); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, ev := range sh.events {
+		if _, ok := ev.(InPayload); ok {
+			t.Fatalf("InPayload should not fire when the call errored, got %#v", sh.events)
+		}
+	}
+	last := sh.events[len(sh.events)-1]
+	end, ok := last.(End)
+	if !ok || end.Error == nil {
+		t.Fatalf("expected the call to end with a non-nil error, got %#v", last)
+	}
+}
+
+// fakeStatsStreamer implements Streamer and statsRequester for
+// StreamTypeHandler.Call. Its NewStream always errors, so the returned
+// *Stream never needs a populated Requests channel: that keeps this test
+// independent of stream.go, which is not part of this source snapshot.
+type fakeStatsStreamer struct {
+	sh  StatsHandler
+	err error
+}
+
+func (f *fakeStatsStreamer) NewStream(ctx context.Context, h HandlerID, payload []byte) (*Stream, error) {
+	return nil, f.err
+}
+
+func (f *fakeStatsStreamer) statsHandler() StatsHandler { return f.sh }
+
+func TestStreamTypeHandlerCallFiresBeginThenEndOnDialError(t *testing.T) {
+	sh := &fakeStatsHandler{}
+	c := &fakeStatsStreamer{sh: sh, err: errors.New("dial failed")}
+	h := NewStream[NoPayload, NoPayload, NoPayload](handlerTest, nil, nil, NewNoPayload)
+
+	if _, err := h.Call(context.Background(), c, NoPayload{}); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if len(sh.events) != 2 {
+		t.Fatalf("expected 2 events (Begin, End), got %d: %#v", len(sh.events), sh.events)
+	}
+	if begin, ok := sh.events[0].(Begin); !ok || !begin.Client {
+		t.Fatalf("expected first event to be a client Begin, got %#v", sh.events[0])
+	}
+	if end, ok := sh.events[1].(End); !ok || !end.Client || end.Error == nil {
+		t.Fatalf("expected last event to be a client End with the dial error, got %#v", sh.events[1])
+	}
+}
+
+// Server-side stats integration (SingleHandler.Register and
+// StreamTypeHandler.register wrapping TagRPC/HandleRPC around the handler
+// dispatch table) is not covered here: it is driven entirely through
+// *Manager, whose implementation lives in manager.go, not part of this
+// source snapshot, so there is no way to register and then invoke a handler
+// in-process to observe it.
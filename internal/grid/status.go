@@ -0,0 +1,150 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+//go:generate stringer -type=Code -output=status_string.go -trimprefix=Code $GOFILE
+
+// Code is a numeric status code describing the outcome of a remote call.
+// Codes are modeled after gRPC's status codes so callers familiar with that
+// convention can reason about retryability and error handling the same way.
+type Code uint8
+
+const (
+	// CodeOK indicates success. Handlers should not return a Status with
+	// this code; reserve it for the zero value of a successful call.
+	CodeOK Code = iota
+	// CodeCanceled indicates the call was canceled, typically by the caller.
+	CodeCanceled
+	// CodeUnknown indicates an error with no more specific code.
+	// A plain RemoteErr is converted to CodeInternal, not CodeUnknown.
+	CodeUnknown
+	// CodeDeadlineExceeded means the call's deadline passed before it completed.
+	CodeDeadlineExceeded
+	// CodeNotFound indicates the requested resource does not exist.
+	CodeNotFound
+	// CodeAlreadyExists indicates the resource a caller tried to create exists.
+	CodeAlreadyExists
+	// CodeFailedPrecondition indicates the system is not in a state required
+	// for the call, e.g. a lock held by another caller. Retrying without
+	// fixing the underlying state will fail identically.
+	CodeFailedPrecondition
+	// CodeResourceExhausted indicates a resource limit was exceeded, e.g.
+	// a connection's flow control window or a rate limit.
+	CodeResourceExhausted
+	// CodeUnauthenticated indicates the caller lacks valid credentials.
+	CodeUnauthenticated
+	// CodeUnavailable indicates the service is currently unavailable.
+	// This is the code most suited for retry with backoff.
+	CodeUnavailable
+	// CodeInternal indicates an internal error in the remote.
+	// RemoteErr values are mapped to this code for backward compatibility.
+	CodeInternal
+)
+
+// StatusDetails is satisfied by a typed payload attached to a Status.
+// It is msgp-serializable like RoundTripper, but unlike RoundTripper it does
+// not embed comparable, since details commonly hold slices or maps that
+// cannot be compared with ==.
+type StatusDetails interface {
+	msgp.Unmarshaler
+	msgp.Marshaler
+	msgp.Sizer
+}
+
+// Status is a structured error returned by a remote handler.
+// It carries a numeric Code in addition to a human-readable message so
+// callers can use errors.Is/errors.As to drive retry, circuit-breaking
+// and lock-manager recovery decisions, instead of parsing error strings.
+type Status struct {
+	Code    Code
+	Message string
+
+	// Details is an optional typed payload providing additional context,
+	// e.g. the lock owner that caused a FailedPrecondition.
+	// It is msgp-serializable so it can cross the wire like any other type.
+	Details StatusDetails
+}
+
+// NewStatus creates a new Status with the given code and message.
+func NewStatus(code Code, msg string) *Status {
+	return &Status{Code: code, Message: msg}
+}
+
+// NewStatusf creates a new Status with the given code and a formatted message.
+func NewStatusf(code Code, format string, a ...any) *Status {
+	return &Status{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// WithDetails attaches a typed details payload to the Status and returns it
+// for chaining.
+func (s *Status) WithDetails(d StatusDetails) *Status {
+	s.Details = d
+	return s
+}
+
+// Error implements the error interface.
+func (s *Status) Error() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s", s.Code, s.Message)
+}
+
+// Is reports whether other is a *Status with the same Code, or a *RemoteErr
+// whose message matches when this Status was converted from one.
+func (s *Status) Is(other error) bool {
+	if s == nil || other == nil {
+		return s == nil && other == nil
+	}
+	var o *Status
+	if errors.As(other, &o) {
+		return s.Code == o.Code
+	}
+	var r *RemoteErr
+	if errors.As(other, &r) {
+		return s.Code == CodeInternal && s.Message == r.Error()
+	}
+	return false
+}
+
+// StatusFromError extracts a *Status from err.
+// A *RemoteErr (returned by handlers predating structured statuses) is
+// mapped to CodeInternal with its string as the message, so existing
+// handlers keep working unmodified. Returns false if err is nil or does
+// not carry status information.
+func StatusFromError(err error) (*Status, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var s *Status
+	if errors.As(err, &s) {
+		return s, true
+	}
+	var r *RemoteErr
+	if errors.As(err, &r) {
+		return &Status{Code: CodeInternal, Message: r.Error()}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCodeString(t *testing.T) {
+	if got := CodeNotFound.String(); got != "NotFound" {
+		t.Fatalf("CodeNotFound.String() = %q, want %q", got, "NotFound")
+	}
+	if got := Code(255).String(); got != "Code(255)" {
+		t.Fatalf("out-of-range Code.String() = %q, want %q", got, "Code(255)")
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	s := NewStatus(CodeNotFound, "object does not exist")
+	want := "NotFound: object does not exist"
+	if got := s.Error(); got != want {
+		t.Fatalf("Error() = %q, want %q", got, want)
+	}
+
+	var nilStatus *Status
+	if got := nilStatus.Error(); got != "" {
+		t.Fatalf("nil *Status.Error() = %q, want \"\"", got)
+	}
+}
+
+func TestStatusIs(t *testing.T) {
+	a := NewStatus(CodeUnavailable, "retry me")
+	b := NewStatus(CodeUnavailable, "different message, same code")
+	c := NewStatus(CodeInternal, "retry me")
+
+	if !a.Is(b) {
+		t.Fatal("two Status values with the same Code should match via Is")
+	}
+	if a.Is(c) {
+		t.Fatal("Status values with different Codes should not match via Is")
+	}
+	if !errors.Is(a, b) {
+		t.Fatal("errors.Is should delegate to Status.Is")
+	}
+}
+
+func TestStatusFromError(t *testing.T) {
+	s := NewStatusf(CodeFailedPrecondition, "lock held by %s", "node-1")
+	got, ok := StatusFromError(s)
+	if !ok || got != s {
+		t.Fatalf("StatusFromError(*Status) = (%v, %v), want (%v, true)", got, ok, s)
+	}
+
+	remote := NewRemoteErrString("legacy handler failure")
+	got, ok = StatusFromError(remote)
+	if !ok {
+		t.Fatal("StatusFromError should convert a *RemoteErr for backward compatibility")
+	}
+	if got.Code != CodeInternal {
+		t.Fatalf("converted RemoteErr Code = %v, want CodeInternal", got.Code)
+	}
+	if got.Message != remote.Error() {
+		t.Fatalf("converted RemoteErr Message = %q, want %q", got.Message, remote.Error())
+	}
+
+	if _, ok := StatusFromError(errors.New("plain error")); ok {
+		t.Fatal("StatusFromError should report false for an error with no status information")
+	}
+	if _, ok := StatusFromError(nil); ok {
+		t.Fatal("StatusFromError(nil) should report false")
+	}
+}
+
+func TestStatusWithDetails(t *testing.T) {
+	s := NewStatus(CodeFailedPrecondition, "lock held").WithDetails(NoPayload{})
+	if s.Details != (NoPayload{}) {
+		t.Fatalf("WithDetails did not attach the details payload")
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2015-2023 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package grid
+
+// Header returns the header Metadata the remote sent back for this stream,
+// or nil if the Streamer passed to Call did not implement headerStreamer or
+// no WithHeader option was given.
+//
+// There is no stream Trailer: unlike a unary Call, a stream has no
+// end-of-call point in this package where a trailer could be captured, so
+// one is not offered here rather than exposing a method that would never
+// report anything.
+func (s *TypedStream[Req, Resp]) Header() Metadata {
+	return s.header
+}